@@ -1,63 +1,277 @@
 package app
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"io"
-	"strings"
+	"regexp"
+	"runtime/debug"
+	"sync/atomic"
+	"text/template"
+	"time"
 
 	"github.com/Royal-Linux/hornero/pkg/config"
+	"github.com/Royal-Linux/hornero/pkg/errctx"
 	"github.com/Royal-Linux/hornero/pkg/i18n"
 	"github.com/Royal-Linux/hornero/pkg/log"
+	"github.com/Royal-Linux/hornero/pkg/reporting"
 	"github.com/Royal-Linux/logrus"
 )
 
+// errorBufferCapacity is how many errors App.LastErrors keeps around
+const errorBufferCapacity = 50
+
 // App struct
 type App struct {
 	closers []io.Closer
 
-	Config        *config.AppConfig
-	Log           *logrus.Entry
-	Tr            *i18n.TranslationSet
-	ErrorChan     chan error
+	Config *config.AppConfig
+	Log    *logrus.Entry
+	Tr     *i18n.TranslationSet
+
+	// ErrCounter is the running total of errors Report has recorded since
+	// startup. Use ErrCount to read it.
+	ErrCounter int64
+
+	// LastErrors holds the most recent errors Report has recorded (up to
+	// errorBufferCapacity), so the GUI can render an error panel without
+	// the user needing to tail the log file.
+	LastErrors *errctx.Buffer
+
+	// RedrawChan is sent to (non-blockingly) whenever Report records a new
+	// error, so the GUI can repaint its error panel/badge without polling.
+	RedrawChan chan struct{}
+
+	// knownErrors is compiled once, at NewApp time, from builtinErrorMappings
+	// plus config.UserConfig.ErrorMappings. See KnownError
+	knownErrors []compiledErrorMapping
 }
 
 // NewApp bootstrap a new application
 func NewApp(config *config.AppConfig) (*App, error) {
 	app := &App{
-		closers:   []io.Closer{},
-		Config:    config,
-		ErrorChan: make(chan error),
+		closers:    []io.Closer{},
+		Config:     config,
+		LastErrors: errctx.NewBuffer(errorBufferCapacity),
+		RedrawChan: make(chan struct{}, 1),
 	}
 	app.Log = log.NewLogger(config, "23432119147a4367abf7c0de2aa99a2d")
-	app.Tr = i18n.NewTranslationSet(app.Log)
+	app.Tr = i18n.NewTranslationSet(app.Log, config.UserConfig.Language, config.ConfigDir)
+	app.knownErrors = compileErrorMappings(app.Log, builtinErrorMappings(), config.UserConfig.ErrorMappings)
+	app.Log.Logger.AddHook(&errCtxHook{app: app})
+
+	if err := reporting.Install(app.Log.Logger, config, app); err != nil {
+		app.Log.Error(err)
+	}
 
 	return app, nil
 }
 
+// MaybePromptForReporting checks whether the user has made a decision about
+// anonymous reporting yet; if not (UserConfig.Reporting == "undetermined")
+// it uses promptYesNo to ask them, using the AnonymousReportingTitle/
+// AnonymousReportingPrompt translations, then persists their answer to
+// config.yml so we don't ask again. The GUI layer is expected to call this
+// once, early, after App is constructed.
+func (app *App) MaybePromptForReporting(promptYesNo func(title, prompt string) bool) error {
+	if app.Config.UserConfig.Reporting != "undetermined" {
+		return nil
+	}
+
+	answer := "off"
+	if promptYesNo(app.Tr.AnonymousReportingTitle, app.Tr.AnonymousReportingPrompt) {
+		answer = "on"
+	}
+
+	app.Config.UserConfig.Reporting = answer
+
+	if err := app.Config.WriteToUserConfig(func(userConfig *config.UserConfig) error {
+		userConfig.Reporting = answer
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if answer == "on" {
+		if err := reporting.Install(app.Log.Logger, app.Config, app); err != nil {
+			app.Log.Error(err)
+		}
+	}
+
+	return nil
+}
+
 func (app *App) Run() error {
 	err := app.Gui.RunWithSubprocesses()
 	return err
 }
 
-type errorMapping struct {
-	originalError string
-	newError      string
+// errCtxReportedField marks a logrus entry as already recorded via Report,
+// so errCtxHook (which also watches app.Log for Error/Panic entries that
+// were logged directly, bypassing Report) doesn't record it a second time.
+const errCtxReportedField = "errctxReported"
+
+// errCtxHook is a logrus.Hook that records any Error/Panic entry logged
+// directly against app.Log (e.g. from a package that only has access to
+// the logger, not the App) into app.LastErrors, the same way an explicit
+// Report call would.
+type errCtxHook struct {
+	app *App
 }
 
-// KnownError takes an error and tells us whether it's an error that we know about where we can print a nicely formatted version of it rather than panicking with a stack trace
-func (app *App) KnownError(err error) (string, bool) {
-	errorMessage := err.Error()
+// Levels implements logrus.Hook
+func (h *errCtxHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.ErrorLevel}
+}
+
+// Fire implements logrus.Hook
+func (h *errCtxHook) Fire(entry *logrus.Entry) error {
+	if reported, _ := entry.Data[errCtxReportedField].(bool); reported {
+		return nil
+	}
+
+	fields := map[string]interface{}{}
+	for key, value := range entry.Data {
+		fields[key] = value
+	}
+
+	knownMessage, _ := h.app.KnownError(errors.New(entry.Message))
+
+	h.app.recordError(errctx.Entry{
+		Time:         entry.Time,
+		Level:        entry.Level.String(),
+		Message:      entry.Message,
+		Fields:       fields,
+		KnownMessage: knownMessage,
+	})
+
+	return nil
+}
+
+// Report records err (with arbitrary structured context, e.g. the docker
+// command executed, container id, or compose file in play) into
+// app.LastErrors, attaches a friendly message via KnownError if there is
+// one, logs the error, and pushes a redraw event so the GUI can update its
+// error panel/badge.
+func (app *App) Report(err error, ctx map[string]interface{}) {
+	knownMessage, _ := app.KnownError(err)
 
-	mappings := []errorMapping{
+	app.recordError(errctx.Entry{
+		Time:         time.Now(),
+		Level:        logrus.ErrorLevel.String(),
+		Message:      err.Error(),
+		Fields:       ctx,
+		Stack:        string(debug.Stack()),
+		KnownMessage: knownMessage,
+	})
+
+	fields := logrus.Fields{errCtxReportedField: true}
+	for key, value := range ctx {
+		fields[key] = value
+	}
+	app.Log.WithFields(fields).Error(err)
+}
+
+// recordError is the shared bookkeeping behind Report and errCtxHook: bump
+// the counter, push onto the ring buffer, and nudge the GUI to redraw.
+func (app *App) recordError(entry errctx.Entry) {
+	atomic.AddInt64(&app.ErrCounter, 1)
+	app.LastErrors.Push(entry)
+
+	select {
+	case app.RedrawChan <- struct{}{}:
+	default:
+	}
+}
+
+// ErrCount returns the number of errors Report (or a direct app.Log.Error
+// call) has recorded since startup, so the status panel can show a
+// persistent "⚠ 3 errors" badge.
+func (app *App) ErrCount() int64 {
+	return atomic.LoadInt64(&app.ErrCounter)
+}
+
+// PopErrorsWithContext returns the errors recorded since the last call
+// (oldest first; only the most recent errorBufferCapacity of them are kept)
+// and empties LastErrors, so a user can review them without tailing the log
+// file.
+func (app *App) PopErrorsWithContext() []errctx.Entry {
+	return app.LastErrors.Pop()
+}
+
+// DumpErrorsJSON writes every currently-recorded error to w as JSON,
+// without clearing LastErrors. It backs the `--errors-json` CLI flag, which
+// the cmd layer is expected to call on exit so that a bug report can
+// include it.
+func (app *App) DumpErrorsJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(app.LastErrors.Snapshot())
+}
+
+// compiledErrorMapping is a config.ErrorMapping with its Pattern compiled to
+// a *regexp.Regexp and its Message parsed as a template, so that neither
+// needs to be redone on every KnownError call.
+type compiledErrorMapping struct {
+	pattern *regexp.Regexp
+	message *template.Template
+}
+
+// builtinErrorMappings are the error patterns hornero recognises out of the
+// box. User-supplied config.UserConfig.ErrorMappings are compiled alongside
+// these and checked afterwards, so a user mapping can't shadow a built-in
+// one but can add new ones.
+func builtinErrorMappings() []config.ErrorMapping {
+	return []config.ErrorMapping{
 		{
-			originalError: "Got permission denied while trying to connect to the Docker daemon socket",
-			newError:      app.Tr.ErrorOccurred,
+			Pattern: regexp.QuoteMeta("Got permission denied while trying to connect to the Docker daemon socket"),
+			Message: "{{.Tr.ErrorOccurred}}",
 		},
 	}
+}
+
+// compileErrorMappings compiles each mapping's Pattern and parses its
+// Message as a template, skipping (and logging) any that fail to compile or
+// parse rather than failing the whole batch.
+func compileErrorMappings(log *logrus.Entry, mappingSets ...[]config.ErrorMapping) []compiledErrorMapping {
+	compiled := []compiledErrorMapping{}
+
+	for _, mappings := range mappingSets {
+		for _, mapping := range mappings {
+			pattern, err := regexp.Compile(mapping.Pattern)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+
+			tmpl, err := template.New("errorMapping").Parse(mapping.Message)
+			if err != nil {
+				log.Error(err)
+				continue
+			}
+
+			compiled = append(compiled, compiledErrorMapping{pattern: pattern, message: tmpl})
+		}
+	}
+
+	return compiled
+}
+
+// KnownError takes an error and tells us whether it's an error that we know about where we can print a nicely formatted version of it rather than panicking with a stack trace
+func (app *App) KnownError(err error) (string, bool) {
+	errorMessage := err.Error()
+
+	for _, mapping := range app.knownErrors {
+		if !mapping.pattern.MatchString(errorMessage) {
+			continue
+		}
 
-	for _, mapping := range mappings {
-		if strings.Contains(errorMessage, mapping.originalError) {
-			return mapping.newError, true
+		var buf bytes.Buffer
+		if err := mapping.message.Execute(&buf, app); err != nil {
+			app.Log.Error(err)
+			continue
 		}
+
+		return buf.String(), true
 	}
 
 	return "", false
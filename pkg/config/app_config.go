@@ -13,34 +13,82 @@
 package config
 
 import (
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/OpenPeeDeeP/xdg"
 	yaml "github.com/jesseduffield/yaml"
+
+	"github.com/Royal-Linux/hornero/pkg/stats"
+	"github.com/Royal-Linux/logrus"
 )
 
+//go:generate go run gen_config_docs.go
+
 // UserConfig holds all of the user-configurable options
 type UserConfig struct {
 	// Reporting determines whether events are reported such as errors (and maybe
 	// application opens but I'm not decided on that yet because it sounds kinda
 	// creepy but I also would love to know how many people are using this
-	// program)
+	// program). One of "on", "off", "undetermined". When "undetermined" the
+	// user is asked on startup, see App.MaybePromptForReporting. Can also be
+	// forced off by setting HORNERO_NO_REPORTING=1
 	Reporting string `yaml:"reporting,omitempty"`
 
+	// ReportingConfig lets you point the anonymous error-reporting hook (see
+	// pkg/reporting) at your own collector, e.g. if you're running a fork.
+	// Only takes effect when Reporting is "on"
+	ReportingConfig ReportingConfig `yaml:"reportingConfig,omitempty"`
+
 	// ConfirmOnQuit when enabled prompts you to confirm you want to quit when you
 	// hit esc or q when no confirmation panels are open
 	ConfirmOnQuit bool `yaml:"confirmOnQuit,omitempty"`
 
+	// Language overrides the language hornero's UI is displayed in. The
+	// default, "auto", detects your language from $LANG (via jibber_jabber);
+	// set this to a BCP-47/POSIX code such as "en", "de_DE", or "zh_CN" to
+	// force a language regardless of what your OS reports.
+	Language string `yaml:"language,omitempty"`
+
 	// OS determines what defaults are set for opening files and links
 	OS OSConfig `yaml:"oS,omitempty"`
 
 	// Stats determines how long hornero will gather os stats for, and
 	// what stat info to graph
 	Stats StatsConfig `yaml:"stats,omitempty"`
+
+	// Keybindings lets you override hornero's default keybindings. It's
+	// keyed by view name (e.g. "containers", "global") then action name
+	// (e.g. "scrollUp") to a key spec like "ctrl+r" or "<f5>". The GUI
+	// layer resolves these at startup instead of using its hard-coded
+	// defaults
+	Keybindings map[string]map[string]string `yaml:"keybindings,omitempty"`
+
+	// ErrorMappings lets you teach hornero about error messages it doesn't
+	// already know how to explain, e.g. a site-specific docker registry
+	// rate-limit or private-registry auth failure. Checked in order, after
+	// hornero's own built-in mappings. See ErrorMapping
+	ErrorMappings []ErrorMapping `yaml:"errorMappings,omitempty"`
+}
+
+// ErrorMapping matches an error's message against Pattern (a regular
+// expression) and, if it matches, tells the user Message instead of the raw
+// error/stack trace. Message may reference a translation key via Go
+// template syntax, e.g. "{{.Tr.ErrorOccurred}}", or just be a literal
+// string.
+type ErrorMapping struct {
+	// Pattern is a regular expression matched against the error's message
+	Pattern string `yaml:"pattern"`
+
+	// Message is shown to the user instead of the original error when
+	// Pattern matches
+	Message string `yaml:"message"`
 }
 
 // OSConfig contains config on the level of the os
@@ -94,6 +142,21 @@ type GraphConfig struct {
 	MaxType string `yaml:"maxType,omitempty"`
 }
 
+// ReportingConfig configures the anonymous error-reporting hook installed by
+// pkg/reporting.
+type ReportingConfig struct {
+	// Endpoint is the HTTPS URL that error reports are POSTed to
+	Endpoint string `yaml:"endpoint,omitempty"`
+
+	// SampleRate is the fraction of eligible errors that actually get
+	// reported, from 0 (none) to 1 (all). Defaults to 1
+	SampleRate float64 `yaml:"sampleRate,omitempty"`
+
+	// IncludeFields is a list of extra logrus field names to attach to a
+	// report, on top of the standard os/version/install-id set
+	IncludeFields []string `yaml:"includeFields,omitempty"`
+}
+
 // StatsConfig contains the stuff relating to stats and graphs
 type StatsConfig struct {
 	// Graphs contains the configuration for the stats graphs we want to show in
@@ -118,6 +181,11 @@ func GetDefaultConfig() UserConfig {
 	return UserConfig{
 		Reporting:     "undetermined",
 		ConfirmOnQuit: false,
+		Language:      "auto",
+		ReportingConfig: ReportingConfig{
+			Endpoint:   "https://reporting.hornero.dev/v1/errors",
+			SampleRate: 1,
+		},
 		OS: GetPlatformDefaultConfig(),
 		Stats: StatsConfig{
 			MaxDuration: duration,
@@ -139,25 +207,26 @@ func GetDefaultConfig() UserConfig {
 
 // AppConfig contains the base configuration fields required for hornero.
 type AppConfig struct {
-	Debug       bool   `long:"debug" env:"DEBUG" default:"false"`
-	Version     string `long:"version" env:"VERSION" default:"unversioned"`
-	Commit      string `long:"commit" env:"COMMIT"`
-	BuildDate   string `long:"build-date" env:"BUILD_DATE"`
-	Name        string `long:"name" env:"NAME" default:"hornero"`
-	BuildSource string `long:"build-source" env:"BUILD_SOURCE" default:""`
-	UserConfig  *UserConfig
-	ConfigDir   string
-	ProjectDir  string
+	Debug          bool   `long:"debug" env:"DEBUG" default:"false"`
+	Version        string `long:"version" env:"VERSION" default:"unversioned"`
+	Commit         string `long:"commit" env:"COMMIT"`
+	BuildDate      string `long:"build-date" env:"BUILD_DATE"`
+	Name           string `long:"name" env:"NAME" default:"hornero"`
+	BuildSource    string `long:"build-source" env:"BUILD_SOURCE" default:""`
+	UserConfig     *UserConfig
+	ConfigDir      string
+	ConfigFilePath string
+	ProjectDir     string
 }
 
 // NewAppConfig makes a new app config
-func NewAppConfig(name, version, commit, date string, buildSource string, debuggingFlag bool, composeFiles []string, projectDir string) (*AppConfig, error) {
-	configDir, err := findOrCreateConfigDir(name)
+func NewAppConfig(name, version, commit, date string, buildSource string, debuggingFlag bool, composeFiles []string, projectDir string, log *logrus.Entry) (*AppConfig, error) {
+	configFilePath, err := findConfigFilename(name, log)
 	if err != nil {
 		return nil, err
 	}
 
-	userConfig, err := loadUserConfigWithDefaults(configDir)
+	userConfig, content, err := loadUserConfigWithDefaults(configFilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -167,21 +236,43 @@ func NewAppConfig(name, version, commit, date string, buildSource string, debugg
 		userConfig.CommandTemplates.DockerCompose += " -f " + strings.Join(composeFiles, " -f ")
 	}
 
+	if err := ValidateUserConfig(content, userConfig); err != nil {
+		log.Warn("config.yml: " + err.Error())
+	}
+
 	appConfig := &AppConfig{
-		Name:        name,
-		Version:     version,
-		Commit:      commit,
-		BuildDate:   date,
-		Debug:       debuggingFlag || os.Getenv("DEBUG") == "TRUE",
-		BuildSource: buildSource,
-		UserConfig:  userConfig,
-		ConfigDir:   configDir,
-		ProjectDir:  projectDir,
+		Name:           name,
+		Version:        version,
+		Commit:         commit,
+		BuildDate:      date,
+		Debug:          debuggingFlag || os.Getenv("DEBUG") == "TRUE",
+		BuildSource:    buildSource,
+		UserConfig:     userConfig,
+		ConfigDir:      filepath.Dir(configFilePath),
+		ConfigFilePath: configFilePath,
+		ProjectDir:     projectDir,
 	}
 
 	return appConfig, nil
 }
 
+// CheckConfig backs the `--check-config` CLI flag: it runs the config
+// search path and validates whatever it finds, without booting the rest of
+// the app. A non-nil error here means the caller should exit non-zero.
+func CheckConfig(name string, log *logrus.Entry) error {
+	configFilePath, err := findConfigFilename(name, log)
+	if err != nil {
+		return err
+	}
+
+	userConfig, content, err := loadUserConfigWithDefaults(configFilePath)
+	if err != nil {
+		return err
+	}
+
+	return ValidateUserConfig(content, userConfig)
+}
+
 func configDirForVendor(vendor string, projectName string) string {
 	envConfigDir := os.Getenv("CONFIG_DIR")
 	if envConfigDir != "" {
@@ -191,57 +282,75 @@ func configDirForVendor(vendor string, projectName string) string {
 	return configDirs.ConfigHome()
 }
 
-func configDir(projectName string) string {
-	legacyConfigDirectory := configDirForVendor("jesseduffield", projectName)
-	if _, err := os.Stat(legacyConfigDirectory); !os.IsNotExist(err) {
-		return legacyConfigDirectory
+// findConfigFilename implements hornero's config file search path: an
+// explicit override via $HORNERO_CONFIG, a project-local ./hornero.yml
+// (handy for per-repo overrides, e.g. in CI), the XDG user config dir, and
+// finally the legacy jesseduffield config dir for anyone upgrading from an
+// older install. The first candidate that exists wins; if none do, the XDG
+// path is created fresh. Whichever path is chosen is logged, so a user
+// filing a bug report can tell us where their config actually came from.
+func findConfigFilename(projectName string, log *logrus.Entry) (string, error) {
+	candidates := []string{}
+
+	if envPath := os.Getenv("HORNERO_CONFIG"); envPath != "" {
+		candidates = append(candidates, envPath)
 	}
-	configDirectory := configDirForVendor("", projectName)
-	return configDirectory
-}
 
-func findOrCreateConfigDir(projectName string) (string, error) {
-	folder := configDir(projectName)
+	candidates = append(candidates,
+		"./hornero.yml",
+		filepath.Join(configDirForVendor("", projectName), "config.yml"),
+		filepath.Join(configDirForVendor("jesseduffield", projectName), "config.yml"),
+	)
 
-	err := os.MkdirAll(folder, 0755)
-	if err != nil {
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			log.Info("using config file: " + candidate)
+			return candidate, nil
+		}
+	}
+
+	fallback := filepath.Join(configDirForVendor("", projectName), "config.yml")
+	if err := os.MkdirAll(filepath.Dir(fallback), 0755); err != nil {
 		return "", err
 	}
 
-	return folder, nil
+	log.Info("no config file found in search path, creating one at: " + fallback)
+
+	return fallback, nil
 }
 
-func loadUserConfigWithDefaults(configDir string) (*UserConfig, error) {
+func loadUserConfigWithDefaults(configFilePath string) (*UserConfig, []byte, error) {
 	config := GetDefaultConfig()
 
-	return loadUserConfig(configDir, &config)
+	return loadUserConfig(configFilePath, &config)
 }
 
-func loadUserConfig(configDir string, base *UserConfig) (*UserConfig, error) {
-	fileName := filepath.Join(configDir, "config.yml")
-
-	if _, err := os.Stat(fileName); err != nil {
+func loadUserConfig(configFilePath string, base *UserConfig) (*UserConfig, []byte, error) {
+	if _, err := os.Stat(configFilePath); err != nil {
 		if os.IsNotExist(err) {
-			file, err := os.Create(fileName)
+			if err := os.MkdirAll(filepath.Dir(configFilePath), 0755); err != nil {
+				return nil, nil, err
+			}
+			file, err := os.Create(configFilePath)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			file.Close()
 		} else {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	content, err := ioutil.ReadFile(fileName)
+	content, err := ioutil.ReadFile(configFilePath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := yaml.Unmarshal(content, base); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return base, nil
+	return base, content, nil
 }
 
 // WriteToUserConfig allows you to set a value on the user config to be saved
@@ -249,7 +358,7 @@ func loadUserConfig(configDir string, base *UserConfig) (*UserConfig, error) {
 // empty string this is because we are using the omitempty yaml directive so
 // that we don't write a heap of zero values to the user's config.yml
 func (c *AppConfig) WriteToUserConfig(updateConfig func(*UserConfig) error) error {
-	userConfig, err := loadUserConfig(c.ConfigDir, &UserConfig{})
+	userConfig, _, err := loadUserConfig(c.ConfigFilePath, &UserConfig{})
 	if err != nil {
 		return err
 	}
@@ -268,5 +377,168 @@ func (c *AppConfig) WriteToUserConfig(updateConfig func(*UserConfig) error) erro
 
 // ConfigFilename returns the filename of the current config file
 func (c *AppConfig) ConfigFilename() string {
-	return filepath.Join(c.ConfigDir, "config.yml")
+	return c.ConfigFilePath
+}
+
+// WriteDefaultConfig writes the default configuration to path as YAML, with
+// every field preceded by the doc comment written above it in this package.
+// Those comments live in config_docs_generated.go, which is regenerated
+// from this file via `go generate ./...` (see gen_config_docs.go) whenever
+// a doc comment changes. Structs held in a list or map (e.g. each graph
+// under stats.graphs) are written by yaml.Marshal, so their fields get no
+// comments.
+func (c *AppConfig) WriteDefaultConfig(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return writeCommentedYAML(file, reflect.ValueOf(GetDefaultConfig()), 0)
+}
+
+// writeCommentedYAML recursively renders v (a struct) as YAML, writing the
+// doc comment for each field (looked up in fieldDocs by "<StructName>.
+// <FieldName>") as a '#'-prefixed block directly above it.
+func writeCommentedYAML(w io.Writer, v reflect.Value, indent int) error {
+	t := v.Type()
+	prefix := strings.Repeat("  ", indent)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		yamlKey := yamlFieldName(field)
+		if yamlKey == "-" {
+			continue
+		}
+
+		if doc, ok := fieldDocs[t.Name()+"."+field.Name]; ok {
+			for _, line := range strings.Split(doc, "\n") {
+				fmt.Fprintf(w, "%s# %s\n", prefix, line)
+			}
+		}
+
+		value := v.Field(i)
+
+		if value.Kind() == reflect.Struct {
+			fmt.Fprintf(w, "%s%s:\n", prefix, yamlKey)
+			if err := writeCommentedYAML(w, value, indent+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		out, err := yaml.Marshal(value.Interface())
+		if err != nil {
+			return err
+		}
+
+		if value.Kind() == reflect.Slice || value.Kind() == reflect.Map {
+			trimmed := strings.TrimRight(string(out), "\n")
+			if value.Len() == 0 {
+				// yaml.Marshal renders a nil/empty slice or map as "{}\n" or
+				// "[]\n" on its own line; writing that on the line below the
+				// key (rather than inline) produces invalid YAML, since
+				// nothing in the document says it belongs to the key above.
+				fmt.Fprintf(w, "%s%s: %s\n", prefix, yamlKey, trimmed)
+				continue
+			}
+
+			fmt.Fprintf(w, "%s%s:\n", prefix, yamlKey)
+			for _, line := range strings.Split(trimmed, "\n") {
+				fmt.Fprintf(w, "%s%s\n", prefix, line)
+			}
+			continue
+		}
+
+		fmt.Fprintf(w, "%s%s: %s", prefix, yamlKey, out)
+	}
+
+	return nil
+}
+
+// yamlFieldName works out the yaml key a struct field is (un)marshalled
+// under, the same way yaml.Marshal itself would: the `yaml` tag name if
+// present, otherwise the field name lowercased.
+func yamlFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("yaml")
+	if tag == "" {
+		return strings.ToLower(field.Name)
+	}
+
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+
+	return name
+}
+
+// validStatPath reports whether path (e.g. "DerivedStats.CPUPercentage")
+// resolves to a real field on stats.RecordedStats, by walking each dotted
+// segment with reflection.
+func validStatPath(path string) bool {
+	t := reflect.TypeOf(stats.RecordedStats{})
+
+	for _, segment := range strings.Split(path, ".") {
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+
+		field, ok := t.FieldByName(segment)
+		if !ok {
+			return false
+		}
+
+		t = field.Type
+	}
+
+	return true
+}
+
+// knownYAMLKeys returns the set of top-level yaml keys a struct will
+// unmarshal into, so ValidateUserConfig can tell a typo'd key from a real one instead
+// of letting yaml.Unmarshal silently drop it.
+func knownYAMLKeys(t reflect.Type) map[string]bool {
+	keys := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		keys[yamlFieldName(t.Field(i))] = true
+	}
+
+	return keys
+}
+
+// ValidateUserConfig checks a config.yml's raw bytes, plus the UserConfig
+// they were merged into, for mistakes yaml.Unmarshal lets through silently:
+// unknown top-level keys, a StatPath that doesn't point at a real stats
+// field, and an inconsistent min/max on a graph.
+func ValidateUserConfig(rawYAML []byte, userConfig *UserConfig) error {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(rawYAML, &raw); err != nil {
+		return err
+	}
+
+	known := knownYAMLKeys(reflect.TypeOf(UserConfig{}))
+	for key := range raw {
+		if !known[key] {
+			return fmt.Errorf("unknown config key: %q", key)
+		}
+	}
+
+	for _, graph := range userConfig.Stats.Graphs {
+		if graph.StatPath != "" && !validStatPath(graph.StatPath) {
+			return fmt.Errorf("graph %q: statPath %q does not exist on stats.RecordedStats", graph.Caption, graph.StatPath)
+		}
+
+		for _, typ := range []string{graph.MinType, graph.MaxType} {
+			if typ != "" && typ != "static" {
+				return fmt.Errorf("graph %q: minType/maxType must be \"\" or \"static\", got %q", graph.Caption, typ)
+			}
+		}
+
+		if graph.MinType == "static" && graph.MaxType == "static" && graph.Min >= graph.Max {
+			return fmt.Errorf("graph %q: min (%v) must be less than max (%v)", graph.Caption, graph.Min, graph.Max)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,100 @@
+package config
+
+import "testing"
+
+func TestValidateUserConfig(t *testing.T) {
+	scenarios := []struct {
+		name       string
+		rawYAML    string
+		userConfig UserConfig
+		wantErr    bool
+	}{
+		{
+			name:       "empty config is valid",
+			rawYAML:    ``,
+			userConfig: UserConfig{},
+			wantErr:    false,
+		},
+		{
+			name:       "known top-level key is valid",
+			rawYAML:    "reporting: \"on\"\n",
+			userConfig: UserConfig{},
+			wantErr:    false,
+		},
+		{
+			name:       "unknown top-level key is rejected",
+			rawYAML:    "reproting: \"on\"\n",
+			userConfig: UserConfig{},
+			wantErr:    true,
+		},
+		{
+			name:    "valid statPath is valid",
+			rawYAML: ``,
+			userConfig: UserConfig{
+				Stats: StatsConfig{
+					Graphs: []GraphConfig{
+						{Caption: "CPU (%)", StatPath: "DerivedStats.CPUPercentage"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "unknown statPath is rejected",
+			rawYAML: ``,
+			userConfig: UserConfig{
+				Stats: StatsConfig{
+					Graphs: []GraphConfig{
+						{Caption: "Bogus", StatPath: "DerivedStats.Nonexistent"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid minType/maxType is rejected",
+			rawYAML: ``,
+			userConfig: UserConfig{
+				Stats: StatsConfig{
+					Graphs: []GraphConfig{
+						{Caption: "CPU (%)", MinType: "bogus"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "static min less than max is valid",
+			rawYAML: ``,
+			userConfig: UserConfig{
+				Stats: StatsConfig{
+					Graphs: []GraphConfig{
+						{Caption: "CPU (%)", MinType: "static", MaxType: "static", Min: 0, Max: 100},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "static min not less than max is rejected",
+			rawYAML: ``,
+			userConfig: UserConfig{
+				Stats: StatsConfig{
+					Graphs: []GraphConfig{
+						{Caption: "CPU (%)", MinType: "static", MaxType: "static", Min: 100, Max: 100},
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := ValidateUserConfig([]byte(s.rawYAML), &s.userConfig)
+			if (err != nil) != s.wantErr {
+				t.Errorf("ValidateUserConfig() error = %v, wantErr %v", err, s.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+//go:build ignore
+
+// This program regenerates config_docs_generated.go from the doc comments
+// written above each field of UserConfig (and the structs it embeds) in
+// app_config.go. Run via `go generate ./...`; see the go:generate directive
+// at the top of app_config.go.
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "app_config.go", nil, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	docs := map[string]string{}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		for _, field := range structType.Fields.List {
+			if field.Doc == nil || len(field.Names) == 0 {
+				continue
+			}
+
+			comment := strings.TrimSpace(field.Doc.Text())
+			docs[typeSpec.Name.Name+"."+field.Names[0].Name] = comment
+		}
+
+		return true
+	})
+
+	keys := make([]string, 0, len(docs))
+	for key := range docs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out, err := os.Create("config_docs_generated.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer out.Close()
+
+	fmt.Fprintln(out, "// Code generated by gen_config_docs.go via go:generate. DO NOT EDIT.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "package config")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "// fieldDocs maps \"<StructName>.<FieldName>\" to the doc comment written")
+	fmt.Fprintln(out, "// above that field, for WriteDefaultConfig to use when emitting a")
+	fmt.Fprintln(out, "// commented config.yml.")
+	fmt.Fprintln(out, "var fieldDocs = map[string]string{")
+	for _, key := range keys {
+		fmt.Fprintf(out, "\t%q: %q,\n", key, docs[key])
+	}
+	fmt.Fprintln(out, "}")
+}
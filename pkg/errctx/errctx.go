@@ -0,0 +1,94 @@
+// Package errctx records structured context around the errors hornero
+// encounters -- the docker command that was run, the container id, which
+// compose file was active, and so on -- so the GUI can show an error panel
+// with that context instead of the user having to tail the log file.
+package errctx
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry is a single recorded error, along with whatever context was
+// attached to it at the point it was reported.
+type Entry struct {
+	Time         time.Time
+	Level        string
+	Message      string
+	Fields       map[string]interface{}
+	Stack        string
+	KnownMessage string
+}
+
+// Buffer is a fixed-capacity ring buffer of Entry, safe for concurrent use.
+// Once full, recording a new entry overwrites the oldest one.
+type Buffer struct {
+	mutex   sync.Mutex
+	entries []Entry
+	start   int
+	size    int
+}
+
+// NewBuffer creates a Buffer that holds at most capacity entries.
+func NewBuffer(capacity int) *Buffer {
+	return &Buffer{entries: make([]Entry, capacity)}
+}
+
+// Push records e, overwriting the oldest entry if the buffer is already at
+// capacity.
+func (b *Buffer) Push(e Entry) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	capacity := len(b.entries)
+	if capacity == 0 {
+		return
+	}
+
+	index := (b.start + b.size) % capacity
+	b.entries[index] = e
+
+	if b.size < capacity {
+		b.size++
+	} else {
+		b.start = (b.start + 1) % capacity
+	}
+}
+
+// Snapshot returns every recorded entry, oldest first, without clearing the
+// buffer.
+func (b *Buffer) Snapshot() []Entry {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	out := make([]Entry, b.size)
+	for i := 0; i < b.size; i++ {
+		out[i] = b.entries[(b.start+i)%len(b.entries)]
+	}
+
+	return out
+}
+
+// Pop returns every recorded entry, oldest first, and empties the buffer.
+func (b *Buffer) Pop() []Entry {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	out := make([]Entry, b.size)
+	for i := 0; i < b.size; i++ {
+		out[i] = b.entries[(b.start+i)%len(b.entries)]
+	}
+
+	b.start = 0
+	b.size = 0
+
+	return out
+}
+
+// Len returns the number of entries currently stored (capped at capacity).
+func (b *Buffer) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.size
+}
@@ -0,0 +1,104 @@
+package errctx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func entry(message string) Entry {
+	return Entry{Message: message}
+}
+
+func TestBufferPushWraparound(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		capacity int
+		pushed   []string
+		expected []string
+	}{
+		{
+			name:     "under capacity",
+			capacity: 3,
+			pushed:   []string{"a", "b"},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "exactly at capacity",
+			capacity: 3,
+			pushed:   []string{"a", "b", "c"},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "wraps once",
+			capacity: 3,
+			pushed:   []string{"a", "b", "c", "d"},
+			expected: []string{"b", "c", "d"},
+		},
+		{
+			name:     "wraps multiple times",
+			capacity: 2,
+			pushed:   []string{"a", "b", "c", "d", "e"},
+			expected: []string{"d", "e"},
+		},
+		{
+			name:     "zero capacity drops everything",
+			capacity: 0,
+			pushed:   []string{"a", "b"},
+			expected: []string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			buf := NewBuffer(s.capacity)
+			for _, message := range s.pushed {
+				buf.Push(entry(message))
+			}
+
+			got := messages(buf.Snapshot())
+			if !reflect.DeepEqual(got, s.expected) {
+				t.Errorf("Snapshot() = %v, want %v", got, s.expected)
+			}
+		})
+	}
+}
+
+func TestBufferPopEmptiesTheBuffer(t *testing.T) {
+	buf := NewBuffer(3)
+	buf.Push(entry("a"))
+	buf.Push(entry("b"))
+
+	got := messages(buf.Pop())
+	want := []string{"a", "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Pop() = %v, want %v", got, want)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Len() after Pop() = %d, want 0", buf.Len())
+	}
+
+	if got := buf.Pop(); len(got) != 0 {
+		t.Errorf("Pop() on empty buffer = %v, want empty", got)
+	}
+}
+
+func TestBufferSnapshotDoesNotClear(t *testing.T) {
+	buf := NewBuffer(3)
+	buf.Push(entry("a"))
+
+	_ = buf.Snapshot()
+
+	if buf.Len() != 1 {
+		t.Errorf("Len() after Snapshot() = %d, want 1", buf.Len())
+	}
+}
+
+func messages(entries []Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Message
+	}
+
+	return out
+}
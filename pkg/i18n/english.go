@@ -1,6 +1,10 @@
 package i18n
 
-// TranslationSet is a set of localised strings for a given language
+// TranslationSet is a set of localised strings for a given language. The
+// zero value of each field is overwritten by whatever en_US.toml defines, so
+// there should be no need to set defaults here in Go code; see
+// translations/dicts/en_US.toml and i18n.go for how a TranslationSet is
+// actually built.
 type TranslationSet struct {
 	ProjectTitle                               string
 	MainTitle                                  string
@@ -12,6 +16,7 @@ type TranslationSet struct {
 	Close                                      string
 	ErrorTitle                                 string
 	EditConfig                                 string
+	OpenConfig                                 string
 	AnonymousReportingTitle                    string
 	AnonymousReportingPrompt                   string
 	ConfirmQuit                                string
@@ -49,59 +54,3 @@ type TranslationSet struct {
 	No  string
 	Yes string
 }
-
-func englishSet() TranslationSet {
-	return TranslationSet{
-		RemovingStatus:             "removing",
-		RestartingStatus:           "restarting",
-		StoppingStatus:             "stopping",
-
-		ErrorOccurred:                     "An error occurred! Please create an issue at https://github.com/Royal-Linux/hornero/issues",
-		ConnectionFailed:                  "connection failed. You may need to restart the client",
-
-		Donate:  "Donate",
-		Confirm: "Confirm",
-
-		Return:              "return",
-		FocusMain:           "focus main panel",
-		Navigate:            "navigate",
-		Execute:             "execute",
-		Close:               "close",
-		Menu:                "menu",
-		Scroll:              "scroll",
-		OpenConfig:          "open hornero config",
-		EditConfig:          "edit hornero config",
-		Cancel:              "cancel",
-		Remove:              "remove",
-		HideStopped:         "Hide/Show stopped containers",
-		ForceRemove:         "force remove",
-		Stop:                "stop",
-		Restart:             "restart",
-		Rebuild:             "rebuild",
-		Recreate:            "recreate",
-		PreviousContext:     "previous tab",
-		NextContext:         "next tab",
-		Attach:              "attach",
-		ViewLogs:            "view logs",
-		ExecShell:           "exec shell",
-
-		AnonymousReportingTitle:  "Help make hornero better",
-		AnonymousReportingPrompt: "Would you like to enable anonymous reporting data to help improve hornero?",
-
-		GlobalTitle:               "Global",
-		MainTitle:                 "Main",
-		ProjectTitle:              "Project",
-		ErrorTitle:                "Error",
-		LogsTitle:                 "Logs",
-		ConfigTitle:               "Config",
-		TopTitle:                  "Top",
-		StatsTitle:                "Stats",
-		CreditsTitle:              "About",
-
-		ConfirmQuit:                "Are you sure you want to quit?",
-		PressEnterToReturn:         "Press enter to return to hornero (this prompt can be disabled in your config by setting `gui.returnImmediately: true`)",
-
-		No:  "no",
-		Yes: "yes",
-	}
-}
@@ -1,14 +1,25 @@
 package i18n
 
 import (
+	"embed"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"reflect"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/imdario/mergo"
 
 	"github.com/cloudfoundry/jibber_jabber"
 	"github.com/Royal-Linux/logrus"
 )
 
+//go:embed translations/dicts/*.toml
+var embeddedDicts embed.FS
+
+const dictsDir = "translations/dicts"
+
 // Localizer will translate a message into the user's language
 type Localizer struct {
 	language string
@@ -16,28 +27,159 @@ type Localizer struct {
 	S        TranslationSet
 }
 
-// NewTranslationSet creates a new Localizer
-func NewTranslationSet(log *logrus.Entry) *TranslationSet {
-	userLang := detectLanguage(jibber_jabber.DetectLanguage)
+// NewTranslationSet creates a new Localizer. language is the value of
+// UserConfig.Language: "auto" (or "") defers to jibber_jabber's detection of
+// $LANG, anything else (e.g. "de_DE") is treated as an explicit override.
+// configDir, if non-empty, is searched for user-supplied overrides under
+// <configDir>/translations/*.toml, which take precedence over the bundled
+// dicts.
+func NewTranslationSet(log *logrus.Entry, language string, configDir string) *TranslationSet {
+	userLang := language
+	if userLang == "" || userLang == "auto" {
+		userLang = detectLanguage(jibber_jabber.DetectLanguage)
+	}
 
 	log.Info("language: " + userLang)
 
-	baseSet := englishSet()
+	baseSet := englishSet(log)
 
-	for languageCode, translationSet := range GetTranslationSets() {
+	for languageCode, translationSet := range GetTranslationSets(log) {
 		if strings.HasPrefix(userLang, languageCode) {
 			_ = mergo.Merge(&baseSet, translationSet, mergo.WithOverride)
 		}
 	}
 
+	for _, translationSet := range userOverrideTranslationSets(log, configDir, userLang) {
+		_ = mergo.Merge(&baseSet, translationSet, mergo.WithOverride)
+	}
+
 	return &baseSet
 }
 
-// GetTranslationSets gets all the translation sets, keyed by language code
-func GetTranslationSets() map[string]TranslationSet {
-	return map[string]TranslationSet{
-		"en": englishSet(),
+// englishSet builds the base translation set from the embedded en_US.toml.
+// Every other set is merged on top of this one, so any key that a
+// non-English dict leaves out naturally falls back to English rather than
+// an empty string.
+func englishSet(log *logrus.Entry) TranslationSet {
+	set, err := loadEmbeddedSet(log, "en_US")
+	if err != nil {
+		log.Error(err)
+	}
+
+	return set
+}
+
+// GetTranslationSets gets all the translation sets bundled with hornero,
+// keyed by language code (e.g. "de" for de_DE.toml, "zh" for zh_CN.toml)
+func GetTranslationSets(log *logrus.Entry) map[string]TranslationSet {
+	entries, err := embeddedDicts.ReadDir(dictsDir)
+	if err != nil {
+		log.Error(err)
+		return map[string]TranslationSet{}
 	}
+
+	sets := map[string]TranslationSet{}
+	for _, entry := range entries {
+		localeName := strings.TrimSuffix(entry.Name(), ".toml")
+		if localeName == "en_US" {
+			continue
+		}
+
+		set, err := loadEmbeddedSet(log, localeName)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		languageCode := strings.SplitN(localeName, "_", 2)[0]
+		sets[languageCode] = set
+	}
+
+	return sets
+}
+
+// userOverrideTranslationSets loads any user-supplied dicts from
+// <configDir>/translations/*.toml, so that a user can patch or add a
+// language without waiting on a hornero release. Only dicts whose locale
+// code is a prefix match for userLang are returned.
+func userOverrideTranslationSets(log *logrus.Entry, configDir string, userLang string) []TranslationSet {
+	if configDir == "" {
+		return nil
+	}
+
+	dir := filepath.Join(configDir, "translations")
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	sets := []TranslationSet{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".toml") {
+			continue
+		}
+
+		localeName := strings.TrimSuffix(entry.Name(), ".toml")
+		languageCode := strings.SplitN(localeName, "_", 2)[0]
+		if !strings.HasPrefix(userLang, languageCode) {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		set, err := decodeTranslationSet(log, data)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+
+		sets = append(sets, set)
+	}
+
+	return sets
+}
+
+// loadEmbeddedSet reads and decodes <dictsDir>/<localeName>.toml from the
+// embedded filesystem. embed.FS always uses forward-slash paths regardless
+// of GOOS, so this must use path.Join rather than filepath.Join.
+func loadEmbeddedSet(log *logrus.Entry, localeName string) (TranslationSet, error) {
+	data, err := embeddedDicts.ReadFile(path.Join(dictsDir, localeName+".toml"))
+	if err != nil {
+		return TranslationSet{}, err
+	}
+
+	return decodeTranslationSet(log, data)
+}
+
+// decodeTranslationSet parses a flat key=value TOML document into a
+// TranslationSet, matching each key to a struct field of the same name via
+// reflection. A key that doesn't correspond to a known field is logged as a
+// warning rather than treated as a failure, so a dict file written against a
+// newer version of hornero doesn't break an older build.
+func decodeTranslationSet(log *logrus.Entry, data []byte) (TranslationSet, error) {
+	raw := map[string]string{}
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return TranslationSet{}, err
+	}
+
+	var set TranslationSet
+	v := reflect.ValueOf(&set).Elem()
+
+	for key, value := range raw {
+		field := v.FieldByName(key)
+		if !field.IsValid() || field.Kind() != reflect.String || !field.CanSet() {
+			log.Warn("unknown translation key: " + key)
+			continue
+		}
+
+		field.SetString(value)
+	}
+
+	return set, nil
 }
 
 // detectLanguage extracts user language from environment
@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Royal-Linux/logrus"
+)
+
+func TestDecodeTranslationSet(t *testing.T) {
+	log := logrus.NewEntry(logrus.New())
+
+	scenarios := []struct {
+		name    string
+		data    string
+		want    TranslationSet
+		wantErr bool
+	}{
+		{
+			name: "known keys are set by field name",
+			data: "Close = \"close\"\nMenu = \"menu\"\n",
+			want: TranslationSet{Close: "close", Menu: "menu"},
+		},
+		{
+			name: "unknown keys are skipped rather than failing",
+			data: "Close = \"close\"\nNotAField = \"whatever\"\n",
+			want: TranslationSet{Close: "close"},
+		},
+		{
+			name:    "invalid TOML is an error",
+			data:    "this is not = = toml",
+			wantErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			got, err := decodeTranslationSet(log, []byte(s.data))
+			if (err != nil) != s.wantErr {
+				t.Fatalf("decodeTranslationSet() error = %v, wantErr %v", err, s.wantErr)
+			}
+
+			if err == nil && got != s.want {
+				t.Errorf("decodeTranslationSet() = %+v, want %+v", got, s.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTranslationSetLogsUnknownKeys(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	log := logrus.NewEntry(logger)
+
+	if _, err := decodeTranslationSet(log, []byte("NotAField = \"whatever\"\n")); err != nil {
+		t.Fatalf("decodeTranslationSet() error = %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("NotAField")) {
+		t.Errorf("expected a warning mentioning the unknown key, got: %s", buf.String())
+	}
+}
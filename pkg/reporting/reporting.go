@@ -0,0 +1,161 @@
+// Package reporting implements hornero's opt-in anonymous error reporting.
+// When UserConfig.Reporting is "on", Install attaches a logrus hook to the
+// app's logger that ships Error/Panic level entries to a configurable HTTPS
+// endpoint, tagged with an anonymous, unidentifiable install id rather than
+// anything that could identify the user.
+package reporting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/denisbrodbeck/machineid"
+	"github.com/Royal-Linux/logrus"
+
+	"github.com/Royal-Linux/hornero/pkg/config"
+)
+
+// installIDSalt is mixed into the machine id (via machineid.ProtectedID) so
+// that the resulting install id can't be correlated with install ids
+// generated by other tools that also call machineid.ProtectedID with their
+// own salt. This is the same key used to salt the log's session id in
+// pkg/log, reused here so we don't need a second constant for the same
+// purpose.
+const installIDSalt = "23432119147a4367abf7c0de2aa99a2d"
+
+// noReportingEnvVar lets operators (or CI) force reporting off regardless of
+// what's in the user's config.yml
+const noReportingEnvVar = "HORNERO_NO_REPORTING"
+
+// report is the JSON body POSTed to Endpoint
+type report struct {
+	InstallID string                 `json:"installId"`
+	Version   string                 `json:"version"`
+	Commit    string                 `json:"commit"`
+	BuildDate string                 `json:"buildDate"`
+	OS        string                 `json:"os"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Stack     string                 `json:"stack,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Time      time.Time              `json:"time"`
+}
+
+// knownErrorChecker is the subset of App that the hook needs. It's declared
+// here rather than imported from pkg/app to avoid an import cycle (pkg/app
+// imports pkg/reporting, not the other way around).
+type knownErrorChecker interface {
+	KnownError(err error) (string, bool)
+}
+
+// Install attaches an anonymous error-reporting hook to log, provided
+// appConfig.UserConfig.Reporting is "on" and HORNERO_NO_REPORTING isn't set
+// to 1.
+// It's a no-op otherwise, so it's safe to call unconditionally on every
+// startup.
+func Install(log *logrus.Logger, appConfig *config.AppConfig, app knownErrorChecker) error {
+	if appConfig.UserConfig.Reporting != "on" {
+		return nil
+	}
+
+	if os.Getenv(noReportingEnvVar) == "1" {
+		return nil
+	}
+
+	installID, err := machineid.ProtectedID(installIDSalt)
+	if err != nil {
+		return err
+	}
+
+	cfg := appConfig.UserConfig.ReportingConfig
+
+	log.AddHook(&hook{
+		cfg:       cfg,
+		installID: installID,
+		version:   appConfig.Version,
+		commit:    appConfig.Commit,
+		buildDate: appConfig.BuildDate,
+		app:       app,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	})
+
+	return nil
+}
+
+// hook is a logrus.Hook that ships Error/Panic entries off to cfg.Endpoint
+type hook struct {
+	cfg       config.ReportingConfig
+	installID string
+	version   string
+	commit    string
+	buildDate string
+	app       knownErrorChecker
+	client    *http.Client
+}
+
+// Levels implements logrus.Hook
+func (h *hook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.ErrorLevel}
+}
+
+// Fire implements logrus.Hook
+func (h *hook) Fire(entry *logrus.Entry) error {
+	if h.cfg.SampleRate < 1 && rand.Float64() > h.cfg.SampleRate {
+		return nil
+	}
+
+	// Known errors are user-actionable (bad docker socket permissions, a
+	// missing compose file, etc), not bugs, so there's nothing for us to
+	// learn from them. hornero logs errors via app.Log.Error/WithFields(...).
+	// Error rather than WithError, so logrus.ErrorKey is never populated;
+	// match on the message instead, same as errCtxHook.Fire does.
+	if _, known := h.app.KnownError(errors.New(entry.Message)); known {
+		return nil
+	}
+
+	fields := map[string]interface{}{}
+	for _, name := range h.cfg.IncludeFields {
+		if value, ok := entry.Data[name]; ok {
+			fields[name] = value
+		}
+	}
+
+	r := report{
+		InstallID: h.installID,
+		Version:   h.version,
+		Commit:    h.commit,
+		BuildDate: h.buildDate,
+		OS:        runtime.GOOS,
+		Level:     entry.Level.String(),
+		Message:   entry.Message,
+		Stack:     string(debug.Stack()),
+		Fields:    fields,
+		Time:      entry.Time,
+	}
+
+	// Shipping the report shouldn't block whatever just logged the error
+	go h.send(r)
+
+	return nil
+}
+
+func (h *hook) send(r report) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+
+	resp, err := h.client.Post(h.cfg.Endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	resp.Body.Close()
+}
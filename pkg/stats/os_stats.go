@@ -0,0 +1,33 @@
+// Package stats records and derives the per-container os stats (CPU,
+// memory, block IO) that hornero graphs in its stats tab.
+package stats
+
+// RecordedStats is a single sample of stats for one container. A
+// GraphConfig.StatPath is a dotted, PascalCase path into this struct, e.g.
+// "DerivedStats.CPUPercentage" or "ClientStats.BlkioStats".
+type RecordedStats struct {
+	// ClientStats is the raw stats as reported by the docker client
+	ClientStats ClientStats
+
+	// DerivedStats are values we've computed from ClientStats for display,
+	// e.g. turning cumulative CPU time into a percentage
+	DerivedStats DerivedStats
+}
+
+// ClientStats mirrors the subset of the docker client's container stats
+// response that hornero cares about
+type ClientStats struct {
+	// BlkioStats is the raw block IO stats for the container
+	BlkioStats string
+}
+
+// DerivedStats are computed from a window of ClientStats samples
+type DerivedStats struct {
+	// CPUPercentage is the container's CPU usage as a percentage of a
+	// single core
+	CPUPercentage float64
+
+	// MemoryPercentage is the container's memory usage as a percentage of
+	// the host's total memory
+	MemoryPercentage float64
+}